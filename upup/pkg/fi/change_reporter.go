@@ -0,0 +1,208 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ChangeReporter receives the change list Context.Render builds when a
+// LifecycleExistsAndValidates or LifecycleExistsAndWarnIfChanges task's
+// actual state doesn't match its desired state. A Context with no
+// ChangeReporter set uses TextChangeReporter, preserving the historical
+// tab-padded stderr output; JSONChangeReporter and JUnitChangeReporter make
+// the same data consumable from CI.
+type ChangeReporter interface {
+	Report(taskName string, lifecycle Lifecycle, changes []*Change) error
+}
+
+// TextChangeReporter writes the change list as tab-padded free-form text,
+// matching what Context.Render has always written to os.Stderr.
+type TextChangeReporter struct {
+	Out io.Writer
+}
+
+var _ ChangeReporter = &TextChangeReporter{}
+
+func (r *TextChangeReporter) Report(taskName string, lifecycle Lifecycle, changes []*Change) error {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "Object from different phase did not match, problems possible:\n")
+	fmt.Fprintf(b, "  %s/%s\n", taskName, "?")
+	for _, change := range changes {
+		lines := strings.Split(change.Description, "\n")
+		if len(lines) == 1 {
+			fmt.Fprintf(b, "  \t%-20s\t%s\n", change.FieldName, change.Description)
+		} else {
+			fmt.Fprintf(b, "  \t%-20s\n", change.FieldName)
+			for _, line := range lines {
+				fmt.Fprintf(b, "  \t%-20s\t%s\n", "", line)
+			}
+		}
+	}
+	fmt.Fprintf(b, "\n")
+	_, err := b.WriteTo(r.Out)
+	return err
+}
+
+// changeRecord is the stable JSON schema for a single reported field
+// mismatch, one per Change returned by buildChangeList.
+type changeRecord struct {
+	Task      string `json:"task"`
+	Lifecycle string `json:"lifecycle"`
+	Field     string `json:"field"`
+	Mismatch  string `json:"mismatch"`
+}
+
+// JSONChangeReporter writes one JSON object per Report call, so CI tooling
+// can parse drift without scraping free-form text.
+type JSONChangeReporter struct {
+	Out io.Writer
+}
+
+var _ ChangeReporter = &JSONChangeReporter{}
+
+func (r *JSONChangeReporter) Report(taskName string, lifecycle Lifecycle, changes []*Change) error {
+	enc := json.NewEncoder(r.Out)
+	for _, change := range changes {
+		record := changeRecord{
+			Task:      taskName,
+			Lifecycle: string(lifecycle),
+			Field:     change.FieldName,
+			Mismatch:  change.Description,
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("error encoding change record for %q: %v", taskName, err)
+		}
+	}
+	return nil
+}
+
+// JUnitChangeReporter accumulates reported mismatches as JUnit testcases -
+// one per task, failed if it has any mismatched fields - so a `kops update`
+// run can be consumed as a test result by CI. Call Flush once RunTasks has
+// finished to emit the testsuite.
+type JUnitChangeReporter struct {
+	cases []junitTestCase
+}
+
+var _ ChangeReporter = &JUnitChangeReporter{}
+
+func (r *JUnitChangeReporter) Report(taskName string, lifecycle Lifecycle, changes []*Change) error {
+	tc := junitTestCase{
+		Name:      taskName,
+		ClassName: "kops.drift." + string(lifecycle),
+	}
+	for _, change := range changes {
+		tc.Failures = append(tc.Failures, junitFailure{
+			Message: change.FieldName,
+			Text:    change.Description,
+		})
+	}
+	r.cases = append(r.cases, tc)
+	return nil
+}
+
+// Flush marshals every Report call made so far into a single JUnit XML
+// testsuite.
+func (r *JUnitChangeReporter) Flush(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:  "kops.drift",
+		Tests: len(r.cases),
+	}
+	for _, tc := range r.cases {
+		if len(tc.Failures) > 0 {
+			suite.Failures += len(tc.Failures)
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(suite)
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// NewChangeReporter builds the ChangeReporter for the given --output value
+// ("text", "json" or "junit"). JUnitChangeReporter additionally needs Flush
+// called once RunTasks finishes; callers that pick "junit" should keep the
+// concrete *JUnitChangeReporter to do so.
+//
+// Nothing in this checkout calls NewChangeReporter yet: there's no cmd/
+// package here to own an --output flag, and Context.ChangeReporter is only
+// ever set to a TextChangeReporter by default (see Context.changeReporter).
+// Wiring an --output flag through to Context.ChangeReporter is left for
+// whatever owns the update cluster command.
+func NewChangeReporter(output string, out io.Writer) (ChangeReporter, error) {
+	switch output {
+	case "", "text":
+		return &TextChangeReporter{Out: out}, nil
+	case "json":
+		return &JSONChangeReporter{Out: out}, nil
+	case "junit":
+		return &JUnitChangeReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown change-reporter output %q", output)
+	}
+}
+
+// ValidationMismatch is one LifecycleExistsAndValidates task whose actual
+// state didn't match its desired state.
+type ValidationMismatch struct {
+	TaskName string
+	Changes  []*Change
+}
+
+// ValidationError is returned by Context.RunTasks once every task has run,
+// listing every LifecycleExistsAndValidates mismatch found along the way.
+// RunTasks only collects mismatches this way when
+// Context.ReportAllValidationMismatches is set; otherwise it fails on the
+// first mismatch and never returns a ValidationError.
+type ValidationError struct {
+	Mismatches []ValidationMismatch
+}
+
+func (e *ValidationError) Error() string {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "%d object(s) did not match expected state:\n", len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(b, "  %s (%d field(s) mismatched)\n", m.TaskName, len(m.Changes))
+	}
+	return b.String()
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTextChangeReporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := &TextChangeReporter{Out: buf}
+
+	changes := []*Change{{FieldName: "Size", Description: "10 -> 20"}}
+	if err := r.Report("my-task", LifecycleExistsAndValidates, changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "my-task") || !strings.Contains(out, "Size") || !strings.Contains(out, "10 -> 20") {
+		t.Errorf("expected output to mention task, field and mismatch, got %q", out)
+	}
+}
+
+func TestJSONChangeReporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := &JSONChangeReporter{Out: buf}
+
+	changes := []*Change{{FieldName: "Size", Description: "10 -> 20"}}
+	if err := r.Report("my-task", LifecycleExistsAndValidates, changes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var record changeRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("error decoding JSON output: %v", err)
+	}
+	if record.Task != "my-task" || record.Field != "Size" || record.Mismatch != "10 -> 20" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestJUnitChangeReporter(t *testing.T) {
+	r := &JUnitChangeReporter{}
+
+	if err := r.Report("ok-task", LifecycleExistsAndValidates, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Report("failing-task", LifecycleExistsAndValidates, []*Change{{FieldName: "Size", Description: "10 -> 20"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.Flush(buf); err != nil {
+		t.Fatalf("unexpected error flushing: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `tests="2"`) {
+		t.Errorf("expected testsuite to report 2 tests, got %q", out)
+	}
+	if !strings.Contains(out, `failures="1"`) {
+		t.Errorf("expected testsuite to report 1 failure, got %q", out)
+	}
+	if !strings.Contains(out, "failing-task") || !strings.Contains(out, "ok-task") {
+		t.Errorf("expected both task names in output, got %q", out)
+	}
+}
+
+func TestNewChangeReporter(t *testing.T) {
+	grid := []struct {
+		output       string
+		expectedType ChangeReporter
+	}{
+		{"", &TextChangeReporter{}},
+		{"text", &TextChangeReporter{}},
+		{"json", &JSONChangeReporter{}},
+		{"junit", &JUnitChangeReporter{}},
+	}
+	for _, g := range grid {
+		reporter, err := NewChangeReporter(g.output, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("NewChangeReporter(%q) returned unexpected error: %v", g.output, err)
+		}
+		if got, want := typeName(reporter), typeName(g.expectedType); got != want {
+			t.Errorf("NewChangeReporter(%q) = %s, expected %s", g.output, got, want)
+		}
+	}
+
+	if _, err := NewChangeReporter("xml", &bytes.Buffer{}); err == nil {
+		t.Errorf("expected error for unknown output %q, got nil", "xml")
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case *TextChangeReporter:
+		return "text"
+	case *JSONChangeReporter:
+		return "json"
+	case *JUnitChangeReporter:
+		return "junit"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache is a set of per-kind Stores, e.g. one each for Instances,
+// SecurityGroups, LoadBalancers, Route53RRsets, IAMRoles and EBSVolumes.
+// Cloud provider packages register a ListWatcher per kind; task Find
+// methods then call Cache.Get(kind, id) instead of calling the cloud API
+// directly. See the package doc comment: no cloud provider package in this
+// checkout actually calls Register yet.
+type Cache struct {
+	mutex  sync.RWMutex
+	stores map[string]*Store
+}
+
+// NewCache returns an empty Cache; call Register for each resource kind
+// before calling Start.
+func NewCache() *Cache {
+	return &Cache{
+		stores: make(map[string]*Store),
+	}
+}
+
+// Register adds a Store for the given kind, backed by listWatch. expiration
+// is the fallback re-list interval used when listWatch can't Watch.
+func (c *Cache) Register(kind string, listWatch ListWatcher, expiration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stores[kind] = NewStore(kind, listWatch, expiration)
+}
+
+// Start primes every registered Store with a full List and begins keeping
+// each one fresh. It blocks until every Store's initial List has completed.
+func (c *Cache) Start(stop <-chan struct{}) error {
+	c.mutex.RLock()
+	stores := make(map[string]*Store, len(c.stores))
+	for kind, store := range c.stores {
+		stores[kind] = store
+	}
+	c.mutex.RUnlock()
+
+	for kind, store := range stores {
+		if err := store.Start(stop); err != nil {
+			return fmt.Errorf("error priming cloudcache for kind %q: %v", kind, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the cached object of the given kind with the given ID.
+func (c *Cache) Get(kind string, id string) (Object, bool) {
+	store := c.storeFor(kind)
+	if store == nil {
+		return nil, false
+	}
+	return store.Get(id)
+}
+
+// GetByName returns every cached object of the given kind with the given
+// name (names aren't unique).
+func (c *Cache) GetByName(kind string, name string) []Object {
+	store := c.storeFor(kind)
+	if store == nil {
+		return nil
+	}
+	return store.GetByName(name)
+}
+
+func (c *Cache) storeFor(kind string) *Store {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.stores[kind]
+}
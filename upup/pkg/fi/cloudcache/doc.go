@@ -0,0 +1,38 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudcache provides a reflector/DeltaFIFO-style caching layer in
+// front of Cloud resource listings, modeled on Kubernetes' client-go
+// informer pattern. Without it, every task's Find method re-hits the cloud
+// API directly, which is a well-known scaling problem on large clusters
+// (hundreds of instance groups mean hundreds of redundant List calls per
+// `kops update`).
+//
+// A Cache holds one typed Store per resource kind (instances, security
+// groups, load balancers, Route53 RRsets, IAM roles, EBS volumes, ...),
+// each backed by a ListWatcher supplied by the cloud provider package. The
+// Cache does a full paginated List to prime each Store, then keeps it
+// fresh by re-listing on an expiration policy or, where the cloud supports
+// it, by watching for changes. Task Find methods should call
+// Cache.Get(kind, id) instead of issuing a fresh API call.
+//
+// Nothing in this checkout calls Cache.Register or sets Context.Cache: the
+// AWS/GCE cloud packages that would supply a ListWatcher per resource kind
+// aren't part of this tree, so this package is unintegrated scaffolding -
+// RunTasks never primes a Cache, and no task Find method calls Cache.Get.
+// Follow-up: wire a ListWatcher from whichever cloud package is added next,
+// then set Context.Cache from the command that builds the Context.
+package cloudcache
@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcache
+
+// Object is any cached cloud resource. ID and Name are used to build the
+// Store's two indexes; Name is frequently a Tag (e.g. the "Name" tag on an
+// AWS resource) rather than a provider-assigned identifier, and may be
+// empty for resources that don't have one.
+type Object interface {
+	CacheID() string
+	CacheName() string
+}
+
+// Delta is a single change reported by a ListWatcher's Watch stream.
+type Delta struct {
+	Type   DeltaType
+	Object Object
+}
+
+type DeltaType string
+
+const (
+	DeltaAdded   DeltaType = "Added"
+	DeltaUpdated DeltaType = "Updated"
+	DeltaDeleted DeltaType = "Deleted"
+)
+
+// ListWatcher knows how to list all objects of a single resource kind, and
+// optionally to keep that list fresh without a full re-list: either by
+// polling with a resourceVersion/ETag so unchanged results are cheap, or by
+// subscribing to a change feed (e.g. AWS Config / EventBridge) where the
+// cloud provider offers one.
+//
+// A ListWatcher that can't watch should simply return ErrWatchNotSupported
+// from Watch; the Store will fall back to re-running List on Expiration.
+type ListWatcher interface {
+	// List returns every object of this kind, plus an opaque
+	// resourceVersion that can be passed back to Watch or to a future
+	// List to detect whether anything changed.
+	List() (objects []Object, resourceVersion string, err error)
+
+	// Watch streams Deltas starting after resourceVersion. The channel is
+	// closed when the watch ends (expires, or the cloud drops it); the
+	// Store will re-List and re-Watch from there.
+	Watch(resourceVersion string) (<-chan Delta, error)
+}
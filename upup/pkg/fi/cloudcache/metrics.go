@@ -0,0 +1,42 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kops",
+		Subsystem: "cloudcache",
+		Name:      "hits_total",
+		Help:      "Number of Get/GetByName calls served from the cache, by kind.",
+	}, []string{"kind"})
+
+	cacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kops",
+		Subsystem: "cloudcache",
+		Name:      "misses_total",
+		Help:      "Number of Get/GetByName calls not found (or expired) in the cache, by kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHits)
+	prometheus.MustRegister(cacheMisses)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcache
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// ErrWatchNotSupported is returned by a ListWatcher whose cloud doesn't
+// offer a way to subscribe to changes; the Store falls back to expiring
+// and re-listing instead.
+var ErrWatchNotSupported = errors.New("watch not supported by this ListWatcher")
+
+// entry is a single cached object plus the bookkeeping needed to expire it.
+type entry struct {
+	object   Object
+	cachedAt time.Time
+}
+
+// Store is a thread-safe, indexed cache of every object of one resource
+// kind (e.g. "ec2.Instance"), kept fresh by a ListWatcher. Objects are
+// indexed by both ID and Name, since callers may know either one.
+type Store struct {
+	kind       string
+	listWatch  ListWatcher
+	expiration time.Duration
+
+	mutex           sync.RWMutex
+	byID            map[string]entry
+	byName          map[string][]string // name -> IDs, since names aren't unique
+	resourceVersion string
+}
+
+// NewStore builds a Store for one resource kind. expiration is how long an
+// entry is trusted: Get and GetByName treat older entries as missing (a
+// non-positive expiration never expires entries). When the ListWatcher
+// can't Watch, expiration is also the interval at which the Store re-Lists
+// (5 minutes if non-positive); while a Watch is open, expired entries are
+// not re-Listed until the Watch ends.
+func NewStore(kind string, listWatch ListWatcher, expiration time.Duration) *Store {
+	return &Store{
+		kind:       kind,
+		listWatch:  listWatch,
+		expiration: expiration,
+		byID:       make(map[string]entry),
+		byName:     make(map[string][]string),
+	}
+}
+
+// Get returns the cached object with the given ID, and whether it was
+// found and not expired.
+func (s *Store) Get(id string) (Object, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	e, ok := s.byID[id]
+	if !ok {
+		cacheMisses.WithLabelValues(s.kind).Inc()
+		return nil, false
+	}
+	if s.expiration > 0 && time.Since(e.cachedAt) > s.expiration {
+		cacheMisses.WithLabelValues(s.kind).Inc()
+		return nil, false
+	}
+	cacheHits.WithLabelValues(s.kind).Inc()
+	return e.object, true
+}
+
+// GetByName returns every cached, non-expired object with the given name
+// (names aren't unique - e.g. a "Name" tag can be reused).
+func (s *Store) GetByName(name string) []Object {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var result []Object
+	for _, id := range s.byName[name] {
+		e, ok := s.byID[id]
+		if !ok {
+			continue
+		}
+		if s.expiration > 0 && time.Since(e.cachedAt) > s.expiration {
+			continue
+		}
+		result = append(result, e.object)
+	}
+	if len(result) == 0 {
+		cacheMisses.WithLabelValues(s.kind).Inc()
+	} else {
+		cacheHits.WithLabelValues(s.kind).Inc()
+	}
+	return result
+}
+
+// Start primes the Store with a full List, then keeps it fresh until stop
+// is closed, by watching if the ListWatcher supports it or by re-Listing
+// on expiration.
+func (s *Store) Start(stop <-chan struct{}) error {
+	if err := s.relist(); err != nil {
+		return err
+	}
+
+	go s.refreshLoop(stop)
+	return nil
+}
+
+func (s *Store) relist() error {
+	objects, resourceVersion, err := s.listWatch.List()
+	if err != nil {
+		return err
+	}
+	s.replace(objects, resourceVersion)
+	return nil
+}
+
+func (s *Store) replace(objects []Object, resourceVersion string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.byID = make(map[string]entry, len(objects))
+	s.byName = make(map[string][]string, len(objects))
+	now := time.Now()
+	for _, o := range objects {
+		s.byID[o.CacheID()] = entry{object: o, cachedAt: now}
+		if name := o.CacheName(); name != "" {
+			s.byName[name] = append(s.byName[name], o.CacheID())
+		}
+	}
+	s.resourceVersion = resourceVersion
+}
+
+func (s *Store) applyDelta(d Delta) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := d.Object.CacheID()
+	if prev, ok := s.byID[id]; ok {
+		s.removeFromNameIndexLocked(prev.object.CacheName(), id)
+	}
+
+	switch d.Type {
+	case DeltaDeleted:
+		delete(s.byID, id)
+	default:
+		s.byID[id] = entry{object: d.Object, cachedAt: time.Now()}
+		if name := d.Object.CacheName(); name != "" {
+			s.byName[name] = append(s.byName[name], id)
+		}
+	}
+}
+
+// removeFromNameIndexLocked removes id from the byName index entry for
+// name. Callers must hold s.mutex.
+func (s *Store) removeFromNameIndexLocked(name, id string) {
+	if name == "" {
+		return
+	}
+	ids := s.byName[name]
+	for i, existing := range ids {
+		if existing == id {
+			s.byName[name] = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(s.byName[name]) == 0 {
+		delete(s.byName, name)
+	}
+}
+
+func (s *Store) refreshLoop(stop <-chan struct{}) {
+	for {
+		s.mutex.RLock()
+		resourceVersion := s.resourceVersion
+		s.mutex.RUnlock()
+
+		watch, err := s.listWatch.Watch(resourceVersion)
+		if err == ErrWatchNotSupported {
+			s.expirationLoop(stop)
+			return
+		}
+		if err != nil {
+			glog.Warningf("cloudcache: watch of %q failed, falling back to expiration: %v", s.kind, err)
+			s.expirationLoop(stop)
+			return
+		}
+
+		for {
+			select {
+			case <-stop:
+				return
+			case delta, ok := <-watch:
+				if !ok {
+					// Watch ended; re-list and re-watch from scratch.
+					if err := s.relist(); err != nil {
+						glog.Warningf("cloudcache: re-list of %q after watch close failed: %v", s.kind, err)
+					}
+					break
+				}
+				s.applyDelta(delta)
+				continue
+			}
+			break
+		}
+	}
+}
+
+func (s *Store) expirationLoop(stop <-chan struct{}) {
+	interval := s.expiration
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := s.relist(); err != nil {
+				glog.Warningf("cloudcache: re-list of %q failed: %v", s.kind, err)
+			}
+		}
+	}
+}
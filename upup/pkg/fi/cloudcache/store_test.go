@@ -0,0 +1,55 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudcache
+
+import "testing"
+
+type fakeObject struct {
+	id   string
+	name string
+}
+
+func (o *fakeObject) CacheID() string   { return o.id }
+func (o *fakeObject) CacheName() string { return o.name }
+
+func TestApplyDelta_KeepsNameIndexLive(t *testing.T) {
+	s := NewStore("fake", nil, 0)
+
+	s.applyDelta(Delta{Type: DeltaAdded, Object: &fakeObject{id: "i-1", name: "instance-a"}})
+
+	if got := s.GetByName("instance-a"); len(got) != 1 {
+		t.Fatalf("expected instance-a to be indexed by name after Added, got %v", got)
+	}
+
+	s.applyDelta(Delta{Type: DeltaUpdated, Object: &fakeObject{id: "i-1", name: "instance-b"}})
+
+	if got := s.GetByName("instance-a"); len(got) != 0 {
+		t.Errorf("expected stale name index entry instance-a to be removed, got %v", got)
+	}
+	if got := s.GetByName("instance-b"); len(got) != 1 {
+		t.Errorf("expected instance-b to be indexed by name after Updated, got %v", got)
+	}
+
+	s.applyDelta(Delta{Type: DeltaDeleted, Object: &fakeObject{id: "i-1", name: "instance-b"}})
+
+	if _, ok := s.Get("i-1"); ok {
+		t.Errorf("expected i-1 to be gone from the ID index after Deleted")
+	}
+	if got := s.GetByName("instance-b"); len(got) != 0 {
+		t.Errorf("expected instance-b to be gone from the name index after Deleted, got %v", got)
+	}
+}
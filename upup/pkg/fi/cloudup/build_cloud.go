@@ -0,0 +1,49 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/openstack"
+)
+
+// BuildCloud constructs the fi.Cloud for a cluster's CloudProvider.
+//
+// Only CloudProvider "openstack" is available in this checkout: the AWS
+// and GCE cloud packages PerformAssignments has historically depended on
+// aren't part of this tree. Any other CloudProvider returns an error
+// rather than silently producing a Cloud that can't do anything.
+func BuildCloud(c *kops.Cluster) (fi.Cloud, error) {
+	switch c.Spec.CloudProvider {
+	case "openstack":
+		return openstack.NewCloud(openstackRegion())
+	default:
+		return nil, fmt.Errorf("BuildCloud: cloud provider %q is not available in this checkout", c.Spec.CloudProvider)
+	}
+}
+
+// openstackRegion reads the region NewCloud authenticates against from the
+// same OS_REGION_NAME environment variable gophercloud's
+// AuthOptionsFromEnv already reads, since ClusterSpec doesn't carry a
+// region field in this checkout.
+func openstackRegion() string {
+	return os.Getenv("OS_REGION_NAME")
+}
@@ -23,7 +23,6 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kops/pkg/apis/kops"
-	"k8s.io/kops/util/pkg/vfs"
 
 	kopsversion "k8s.io/kops"
 )
@@ -43,6 +42,9 @@ func PerformAssignments(c *kops.Cluster) error {
 		return err
 	}
 
+	// FindVPCInfo is implemented per-cloud (e.g. openstack.Cloud resolves
+	// NetworkID against a Neutron network), so a shared network is inferred
+	// the same way regardless of CloudProvider.
 	if c.SharedVPC() && c.Spec.NetworkCIDR == "" {
 		vpcInfo, err := cloud.FindVPCInfo(c.Spec.NetworkID)
 		if err != nil {
@@ -113,7 +115,7 @@ func ensureKubernetesVersion(c *kops.Cluster) error {
 	}
 
 	if c.Spec.KubernetesVersion == "" {
-		latestVersion, err := FindLatestKubernetesVersion()
+		latestVersion, err := resolveLatestKubernetesVersion()
 		if err != nil {
 			return err
 		}
@@ -123,20 +125,20 @@ func ensureKubernetesVersion(c *kops.Cluster) error {
 	return nil
 }
 
-// FindLatestKubernetesVersion returns the latest kubernetes version,
-// as stored at https://storage.googleapis.com/kubernetes-release/release/stable.txt
-// This shouldn't be used any more; we prefer reading the stable channel
+// FindLatestKubernetesVersion returns the latest kubernetes version, as
+// stored at https://storage.googleapis.com/kubernetes-release/release/stable.txt
+// This shouldn't be used any more; we prefer reading the stable channel.
+//
+// Deprecated: use a VersionResolver (for example GCSStableVersionResolver)
+// instead; this function is kept for existing callers.
 func FindLatestKubernetesVersion() (string, error) {
-	stableURL := "https://storage.googleapis.com/kubernetes-release/release/stable.txt"
-	glog.Warningf("Loading latest kubernetes version from %q", stableURL)
-	b, err := vfs.Context.ReadFile(stableURL)
-	if err != nil {
-		return "", fmt.Errorf("KubernetesVersion not specified, and unable to download latest version from %q: %v", stableURL, err)
-	}
-	latestVersion := strings.TrimSpace(string(b))
-	return latestVersion, nil
+	return (&GCSStableVersionResolver{}).FindLatestKubernetesVersion()
 }
 
+// metadataServiceNoProxy is the link-local address both aws and openstack
+// serve their instance metadata service from.
+const metadataServiceNoProxy = "169.254.169.254"
+
 func assignProxy(cluster *kops.Cluster) (*kops.EgressProxySpec, error) {
 
 	egressProxy := cluster.Spec.EgressProxy
@@ -176,10 +178,14 @@ func assignProxy(cluster *kops.Cluster) (*kops.EgressProxySpec, error) {
 			}
 		}
 
-		awsNoProxy := "169.254.169.254"
-
-		if cluster.Spec.CloudProvider == "aws" && !strings.Contains(cluster.Spec.EgressProxy.ProxyExcludes, awsNoProxy) {
-			egressSlice = append(egressSlice, awsNoProxy)
+		// aws and openstack both serve instance metadata from this
+		// link-local address. (OpenStack nodes reading metadata from the
+		// config-drive instead go straight to the local block device,
+		// which never goes through an HTTP proxy, so there's nothing to
+		// add to ProxyExcludes for that path.)
+		if (cluster.Spec.CloudProvider == "aws" || cluster.Spec.CloudProvider == "openstack") &&
+			!strings.Contains(cluster.Spec.EgressProxy.ProxyExcludes, metadataServiceNoProxy) {
+			egressSlice = append(egressSlice, metadataServiceNoProxy)
 		}
 
 		// the kube-apiserver will need to talk to kubelets on their node IP addresses port 10250
@@ -192,6 +198,16 @@ func assignProxy(cluster *kops.Cluster) (*kops.EgressProxySpec, error) {
 			glog.Warningf("No NetworkCIDR defined (yet), not adding to egressProxy.excludes")
 		}
 
+		// Note: we deliberately don't add a VersionResolver's host to the
+		// excludes list here. For the default GCSStableVersionResolver in
+		// particular, storage.googleapis.com is exactly the kind of address
+		// an egress proxy exists to reach in an air-gapped install -
+		// excluding it would make kops try to hit it directly and fail.
+		// Once ClusterSpec exposes VersionResolvers, excluding a resolver's
+		// host should only happen for a resolver the operator explicitly
+		// configured as bypassing the proxy (e.g. an internal VFS mirror),
+		// not for every cluster regardless of CloudProvider or configuration.
+
 		egressProxy.ProxyExcludes = strings.Join(egressSlice, ",")
 		glog.V(8).Infof("Completed setting up Proxy excludes as follows: %q", egressProxy.ProxyExcludes)
 	} else {
@@ -0,0 +1,241 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// VersionResolver knows how to find the latest available Kubernetes
+// version from some upstream source. resolveLatestKubernetesVersion
+// consults a list of these in order. ensureKubernetesVersion passes it no
+// list, so it only ever uses the default GCSStableVersionResolver; the
+// other resolvers are used only by callers that pass them explicitly.
+type VersionResolver interface {
+	// FindLatestKubernetesVersion returns the latest Kubernetes version
+	// known to this resolver.
+	FindLatestKubernetesVersion() (string, error)
+
+	// Host returns the host part of the location this resolver reads from
+	// (for a VFS path such as s3://bucket/..., that is the bucket name).
+	// Empty if the location has no host (e.g. a file:// path). assignProxy
+	// does not consult it; no resolver host is added to the egress proxy
+	// excludes.
+	Host() string
+}
+
+const defaultStableURL = "https://storage.googleapis.com/kubernetes-release/release/stable.txt"
+
+// GCSStableVersionResolver reads the well-known stable.txt object from the
+// public kubernetes-release GCS bucket. This is the historical default.
+type GCSStableVersionResolver struct {
+	// URL overrides the default public stable.txt location, e.g. to point
+	// at a private mirror that still serves the same plain-text format.
+	URL string
+}
+
+func (r *GCSStableVersionResolver) url() string {
+	if r.URL != "" {
+		return r.URL
+	}
+	return defaultStableURL
+}
+
+func (r *GCSStableVersionResolver) FindLatestKubernetesVersion() (string, error) {
+	stableURL := r.url()
+	glog.Warningf("Loading latest kubernetes version from %q", stableURL)
+	b, err := vfs.Context.ReadFile(stableURL)
+	if err != nil {
+		return "", fmt.Errorf("KubernetesVersion not specified, and unable to download latest version from %q: %v", stableURL, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (r *GCSStableVersionResolver) Host() string {
+	return hostOf(r.url())
+}
+
+// VFSVersionResolver reads a stable-version marker file from an arbitrary
+// VFS path (s3://, gs://, file://, ...), letting operators mirror
+// stable.txt inside their own object store.
+type VFSVersionResolver struct {
+	Path string
+}
+
+func (r *VFSVersionResolver) FindLatestKubernetesVersion() (string, error) {
+	if r.Path == "" {
+		return "", fmt.Errorf("VFSVersionResolver requires a Path")
+	}
+	glog.Infof("Loading latest kubernetes version from %q", r.Path)
+	b, err := vfs.Context.ReadFile(r.Path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read kubernetes version from %q: %v", r.Path, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (r *VFSVersionResolver) Host() string {
+	return hostOf(r.Path)
+}
+
+// OCIVersionResolver discovers the latest Kubernetes version from the tag
+// list of a mirrored registry.k8s.io/kube-apiserver image, for installs
+// that ship their own builds through a private registry mirror.
+type OCIVersionResolver struct {
+	// Registry is the registry host, e.g. "registry.example.com".
+	Registry string
+	// Repository is the image path, e.g. "kube-apiserver".
+	Repository string
+}
+
+type ociTagList struct {
+	Tags []string `json:"tags"`
+}
+
+func (r *OCIVersionResolver) FindLatestKubernetesVersion() (string, error) {
+	if r.Registry == "" || r.Repository == "" {
+		return "", fmt.Errorf("OCIVersionResolver requires a Registry and Repository")
+	}
+
+	tagsURL := fmt.Sprintf("https://%s/v2/%s/tags/list", r.Registry, r.Repository)
+	glog.Infof("Loading kubernetes version tags from %q", tagsURL)
+
+	resp, err := http.Get(tagsURL)
+	if err != nil {
+		return "", fmt.Errorf("error listing tags from %q: %v", tagsURL, err)
+	}
+	defer resp.Body.Close()
+
+	var tagList ociTagList
+	if err := json.NewDecoder(resp.Body).Decode(&tagList); err != nil {
+		return "", fmt.Errorf("error decoding tag list from %q: %v", tagsURL, err)
+	}
+	if len(tagList.Tags) == 0 {
+		return "", fmt.Errorf("no tags found at %q", tagsURL)
+	}
+
+	latest := tagList.Tags[0]
+	for _, tag := range tagList.Tags[1:] {
+		if compareKubernetesVersions(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	return latest, nil
+}
+
+func (r *OCIVersionResolver) Host() string {
+	return r.Registry
+}
+
+// compareKubernetesVersions compares two "vX.Y.Z"-style tags numerically,
+// component by component, so "v1.9.0" is correctly treated as older than
+// "v1.10.0" (a plain lexical comparison would get this backwards). It
+// returns a negative number, zero or a positive number as a < b, a == b or
+// a > b, following the usual comparison convention. Tags that don't parse
+// as dotted numeric versions sort before ones that do.
+func compareKubernetesVersions(a, b string) int {
+	av, aok := parseDottedVersion(a)
+	bv, bok := parseDottedVersion(b)
+	if !aok || !bok {
+		if aok != bok {
+			if aok {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	}
+
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var ac, bc int
+		if i < len(av) {
+			ac = av[i]
+		}
+		if i < len(bv) {
+			bc = bv[i]
+		}
+		if ac != bc {
+			return ac - bc
+		}
+	}
+	return 0
+}
+
+// parseDottedVersion parses the numeric components of a "vX.Y.Z"-style tag,
+// ignoring a leading "v" and any "-"-delimited pre-release/build suffix
+// (e.g. "v1.10.0-beta.1" -> [1, 10, 0]).
+func parseDottedVersion(tag string) ([]int, bool) {
+	tag = strings.TrimPrefix(tag, "v")
+	if tag == "" {
+		return nil, false
+	}
+	if idx := strings.IndexByte(tag, '-'); idx >= 0 {
+		tag = tag[:idx]
+	}
+
+	parts := strings.Split(tag, ".")
+	components := make([]int, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			return nil, false
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		components = append(components, n)
+	}
+	return components, true
+}
+
+func hostOf(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Host
+}
+
+// resolveLatestKubernetesVersion walks resolvers in order and returns the
+// first version found. kops.ClusterSpec doesn't yet expose a
+// VersionResolvers field in this checkout, so callers that don't have an
+// explicit list fall back to the historical GCSStableVersionResolver,
+// keeping existing behavior unchanged.
+func resolveLatestKubernetesVersion(resolvers ...VersionResolver) (string, error) {
+	if len(resolvers) == 0 {
+		resolvers = []VersionResolver{&GCSStableVersionResolver{}}
+	}
+
+	var lastErr error
+	for _, resolver := range resolvers {
+		version, err := resolver.FindLatestKubernetesVersion()
+		if err == nil {
+			return version, nil
+		}
+		lastErr = err
+		glog.Warningf("version resolver failed, trying the next one: %v", err)
+	}
+	return "", lastErr
+}
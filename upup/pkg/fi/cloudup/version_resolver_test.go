@@ -0,0 +1,143 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVFSVersionResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfsresolver")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stablePath := filepath.Join(dir, "stable.txt")
+	if err := ioutil.WriteFile(stablePath, []byte("v1.7.2\n"), 0644); err != nil {
+		t.Fatalf("error writing fake stable.txt: %v", err)
+	}
+
+	resolver := &VFSVersionResolver{Path: "file://" + stablePath}
+	version, err := resolver.FindLatestKubernetesVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "v1.7.2" {
+		t.Errorf("expected version %q, got %q", "v1.7.2", version)
+	}
+}
+
+func TestVFSVersionResolver_MissingPath(t *testing.T) {
+	resolver := &VFSVersionResolver{}
+	if _, err := resolver.FindLatestKubernetesVersion(); err == nil {
+		t.Errorf("expected error for empty Path, got nil")
+	}
+}
+
+func TestVFSVersionResolver_NotFound(t *testing.T) {
+	resolver := &VFSVersionResolver{Path: "file:///no/such/path/stable.txt"}
+	if _, err := resolver.FindLatestKubernetesVersion(); err == nil {
+		t.Errorf("expected error for missing file, got nil")
+	}
+}
+
+func TestResolveLatestKubernetesVersion_FallsThroughToNextResolver(t *testing.T) {
+	broken := &VFSVersionResolver{Path: "file:///no/such/path/stable.txt"}
+
+	dir, err := ioutil.TempDir("", "vfsresolver")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stablePath := filepath.Join(dir, "stable.txt")
+	if err := ioutil.WriteFile(stablePath, []byte("v1.8.0"), 0644); err != nil {
+		t.Fatalf("error writing fake stable.txt: %v", err)
+	}
+	working := &VFSVersionResolver{Path: "file://" + stablePath}
+
+	version, err := resolveLatestKubernetesVersion(broken, working)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "v1.8.0" {
+		t.Errorf("expected version %q, got %q", "v1.8.0", version)
+	}
+}
+
+func TestCompareKubernetesVersions(t *testing.T) {
+	grid := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.9.0", "v1.10.0", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.10.0", "v1.10.0", 0},
+		{"v1.2.0", "v1.10.0", -1},
+		{"v1.10.2", "v1.10.10", -1},
+		{"v1.10.0-beta.1", "v1.10.0", 0},
+	}
+	for _, g := range grid {
+		actual := compareKubernetesVersions(g.a, g.b)
+		switch {
+		case g.expected < 0 && actual >= 0:
+			t.Errorf("compareKubernetesVersions(%q, %q) = %d, expected negative", g.a, g.b, actual)
+		case g.expected > 0 && actual <= 0:
+			t.Errorf("compareKubernetesVersions(%q, %q) = %d, expected positive", g.a, g.b, actual)
+		case g.expected == 0 && actual != 0:
+			t.Errorf("compareKubernetesVersions(%q, %q) = %d, expected 0", g.a, g.b, actual)
+		}
+	}
+}
+
+// TestCompareKubernetesVersions_PicksHighestTag runs the same selection loop
+// OCIVersionResolver.FindLatestKubernetesVersion uses over a tag list; it
+// does not call the resolver itself.
+func TestCompareKubernetesVersions_PicksHighestTag(t *testing.T) {
+	tags := []string{"v1.2.0", "v1.10.0", "v1.9.5"}
+	latest := tags[0]
+	for _, tag := range tags[1:] {
+		if compareKubernetesVersions(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	if latest != "v1.10.0" {
+		t.Errorf("expected v1.10.0 to be picked as latest, got %q", latest)
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	grid := []struct {
+		url      string
+		expected string
+	}{
+		{"https://storage.googleapis.com/kubernetes-release/release/stable.txt", "storage.googleapis.com"},
+		{"s3://my-bucket/stable.txt", "my-bucket"},
+		{"file:///tmp/stable.txt", ""},
+		{"not a url", ""},
+	}
+	for _, g := range grid {
+		actual := hostOf(g.url)
+		if actual != g.expected {
+			t.Errorf("hostOf(%q) = %q, expected %q", g.url, actual, g.expected)
+		}
+	}
+}
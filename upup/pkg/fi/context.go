@@ -17,15 +17,16 @@ limitations under the License.
 package fi
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/golang/glog"
 	"io/ioutil"
+	"k8s.io/kops/upup/pkg/fi/cloudcache"
 	"k8s.io/kops/util/pkg/vfs"
 	"k8s.io/kubernetes/federation/pkg/dnsprovider"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -41,7 +42,40 @@ type Context struct {
 
 	CheckExisting bool
 
-	tasks map[string]Task
+	// LiveState, if set, is started at the beginning of RunTasks and polls
+	// for drift while the task graph runs. RunTasks stops it when it
+	// returns, so polling does not continue after this apply. See
+	// LiveStateStore. Nothing in this checkout sets this field; see the
+	// livestate package doc comment.
+	LiveState LiveStateStore
+
+	// Cache, if set, is primed at the beginning of RunTasks and kept fresh
+	// until RunTasks returns. See cloudcache.Cache. Nothing in this checkout
+	// sets this field, and no task Find method reads from it; see the
+	// cloudcache package doc comment.
+	Cache *cloudcache.Cache
+
+	// ChangeReporter receives the change list for
+	// LifecycleExistsAndValidates/LifecycleExistsAndWarnIfChanges tasks.
+	// Defaults to a TextChangeReporter writing to os.Stderr, matching the
+	// historical behavior. Nothing sets this from an --output flag in this
+	// checkout; see NewChangeReporter.
+	ChangeReporter ChangeReporter
+
+	// ReportAllValidationMismatches changes how a LifecycleExistsAndValidates
+	// mismatch is handled. By default (false) Render fails fast, the same as
+	// it always has: RunTasks returns the first mismatch as an error and the
+	// rest of the task graph does not run. When true, Render instead records
+	// the mismatch via recordValidationMismatch and lets the task graph keep
+	// running, so RunTasks can return every mismatch found in the pass as a
+	// single *ValidationError. Only set this for read-only/dry-run style
+	// callers that want a full report and are not relying on fail-fast to
+	// stop mutating tasks from running after a validation failure.
+	ReportAllValidationMismatches bool
+
+	tasks                     map[string]Task
+	validationMismatchesMutex sync.Mutex
+	validationMismatches      []ValidationMismatch
 }
 
 func NewContext(target Target, cloud Cloud, keystore Keystore, secretStore SecretStore, clusterConfigBase vfs.Path, checkExisting bool, tasks map[string]Task) (*Context, error) {
@@ -68,11 +102,55 @@ func (c *Context) AllTasks() map[string]Task {
 	return c.tasks
 }
 
+// RunTasks runs the task graph to completion. A LifecycleExistsAndValidates
+// mismatch fails the whole run immediately unless
+// ReportAllValidationMismatches is set, in which case every mismatch found
+// along the way is collected and returned together as a *ValidationError
+// once the graph finishes running.
 func (c *Context) RunTasks(maxTaskDuration time.Duration) error {
+	if c.Cache != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		if err := c.Cache.Start(stop); err != nil {
+			return fmt.Errorf("error priming cloud cache: %v", err)
+		}
+	}
+
+	if c.LiveState != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		if err := c.LiveState.Start(stop); err != nil {
+			return fmt.Errorf("error starting live state store: %v", err)
+		}
+	}
+
 	e := &executor{
 		context: c,
 	}
-	return e.RunTasks(c.tasks, maxTaskDuration)
+	if err := e.RunTasks(c.tasks, maxTaskDuration); err != nil {
+		return err
+	}
+
+	c.validationMismatchesMutex.Lock()
+	mismatches := c.validationMismatches
+	c.validationMismatchesMutex.Unlock()
+	if len(mismatches) > 0 {
+		return &ValidationError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+func (c *Context) changeReporter() ChangeReporter {
+	if c.ChangeReporter != nil {
+		return c.ChangeReporter
+	}
+	return &TextChangeReporter{Out: os.Stderr}
+}
+
+func (c *Context) recordValidationMismatch(taskName string, changes []*Change) {
+	c.validationMismatchesMutex.Lock()
+	defer c.validationMismatchesMutex.Unlock()
+	c.validationMismatches = append(c.validationMismatches, ValidationMismatch{TaskName: taskName, Changes: changes})
 }
 
 func (c *Context) Close() {
@@ -117,36 +195,32 @@ func (c *Context) Render(a, e, changes Task) error {
 		} else {
 			switch *lifecycle {
 			case LifecycleExistsAndValidates, LifecycleExistsAndWarnIfChanges:
-				out := os.Stderr
 				changeList, err := buildChangeList(a, e, changes)
 				if err != nil {
 					return err
 				}
 
-				b := &bytes.Buffer{}
 				taskName := getTaskName(e)
-				fmt.Fprintf(b, "Object from different phase did not match, problems possible:\n")
-				fmt.Fprintf(b, "  %s/%s\n", taskName, "?")
-				for _, change := range changeList {
-					lines := strings.Split(change.Description, "\n")
-					if len(lines) == 1 {
-						fmt.Fprintf(b, "  \t%-20s\t%s\n", change.FieldName, change.Description)
-					} else {
-						fmt.Fprintf(b, "  \t%-20s\n", change.FieldName)
-						for _, line := range lines {
-							fmt.Fprintf(b, "  \t%-20s\t%s\n", "", line)
-						}
-					}
+				if err := c.changeReporter().Report(taskName, *lifecycle, changeList); err != nil {
+					glog.Warningf("error reporting changes for %s: %v", taskName, err)
 				}
-				fmt.Fprintf(b, "\n")
-				b.WriteTo(out)
 
 				if *lifecycle == LifecycleExistsAndValidates {
-					return fmt.Errorf("Lifecycle set to ExistsAndValidates, but object did not match")
-				} else {
-					// Warn, but then we continue
+					if !c.ReportAllValidationMismatches {
+						// Fail fast, as this has always done: stop before
+						// any task that runs after this one in the graph
+						// mutates anything on the assumption that this
+						// object matched.
+						return fmt.Errorf("Lifecycle set to ExistsAndValidates, but object did not match")
+					}
+					// Record the mismatch and keep going, so RunTasks can
+					// report every validation failure in this pass instead
+					// of making operators re-run repeatedly to find them all.
+					c.recordValidationMismatch(taskName, changeList)
 					return nil
 				}
+				// Warn, but then we continue
+				return nil
 			}
 		}
 	}
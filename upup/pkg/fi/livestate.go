@@ -0,0 +1,54 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"time"
+)
+
+// LiveStateStore is an optional, continuously-updated view of the actual
+// cloud state for the tasks in a Context. When a Context.LiveState is set,
+// RunTasks starts it alongside the normal task execution and stops it when
+// RunTasks returns, so from a Context it only observes drift while that run
+// is in progress. Implementations live in fi/livestate, which polls the
+// Cloud/DNS/Keystore providers on a per-resource-class schedule and diffs
+// the observed object against the task graph using DiffTask.
+type LiveStateStore interface {
+	// Start begins polling in the background. It returns once the first
+	// full pass has completed; polling continues until stop is closed.
+	Start(stop <-chan struct{}) error
+
+	// Events returns the channel on which DriftEvents are published.
+	Events() <-chan DriftEvent
+}
+
+// DriftEvent describes a single task whose last-observed state no longer
+// matches the desired state, as found by a LiveStateStore poll.
+type DriftEvent struct {
+	TaskName   string
+	Changes    []*Change
+	ObservedAt time.Time
+}
+
+// DiffTask compares the actual and expected state of a single task and
+// returns the resulting list of changes. It is the same comparison Render
+// uses for LifecycleExistsAndValidates/LifecycleExistsAndWarnIfChanges
+// tasks, exported so that out-of-band consumers such as a LiveStateStore
+// can reuse it without duplicating Render's reflection-based diffing.
+func DiffTask(a, e, changes Task) ([]*Change, error) {
+	return buildChangeList(a, e, changes)
+}
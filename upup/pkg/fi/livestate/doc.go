@@ -0,0 +1,36 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package livestate implements fi.LiveStateStore: a background poller that
+// keeps a last-seen-actual-object cache for every task in a fi.Context and
+// streams fi.DriftEvents whenever the observed cloud state no longer
+// matches the desired task graph.
+//
+// A Store has no built-in knowledge of any particular cloud; callers
+// register a Getter per resource kind (AWS EC2 instances, ELBs, Route53
+// RRsets, GCE instances, ...), and the Store takes care of scheduling,
+// caching and diffing. A `kops drift-detector` command, or a
+// controller-manager style reconciliation loop, can consume Events()
+// without needing to run a full `kops update`.
+//
+// Nothing in this checkout sets Context.LiveState or registers a Getter:
+// the AWS/GCE cloud packages a Getter would poll through aren't part of
+// this tree. Until one of those cloud packages lands here and registers a
+// Getter, this package is unintegrated scaffolding - RunTasks never starts
+// a Store. Follow-up: wire a Getter from whichever cloud package is added
+// next, then set Context.LiveState from the command that builds the
+// Context.
+package livestate
@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livestate
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	pollLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kops",
+		Subsystem: "livestate",
+		Name:      "poll_duration_seconds",
+		Help:      "Time taken to poll the actual state of a single task.",
+	}, []string{"kind"})
+
+	pollErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kops",
+		Subsystem: "livestate",
+		Name:      "poll_errors_total",
+		Help:      "Number of errors encountered while polling actual state.",
+	}, []string{"kind"})
+
+	driftEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kops",
+		Subsystem: "livestate",
+		Name:      "drift_events_total",
+		Help:      "Number of DriftEvents published, by task kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	prometheus.MustRegister(pollLatency)
+	prometheus.MustRegister(pollErrors)
+	prometheus.MustRegister(driftEventsTotal)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livestate
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// Getter fetches the actual cloud state for a single task. Each resource
+// kind (AWS EC2 instance, ELB, Route53 RRset, GCE instance, ...) supplies
+// its own Getter; the Store itself has no cloud-specific knowledge.
+type Getter interface {
+	// Get returns the actual object currently observed in the cloud for
+	// the given desired task, or nil if it does not exist.
+	Get(task fi.Task) (fi.Task, error)
+}
+
+// PollConfig controls how often a resource kind is re-polled.
+type PollConfig struct {
+	Interval time.Duration
+	// Jitter is added (randomly, up to this amount) to Interval on every
+	// poll, so that many tasks of the same kind don't all hit the cloud
+	// API in the same instant.
+	Jitter time.Duration
+}
+
+// DefaultPollConfig is used for any kind that hasn't been given a more
+// specific PollConfig via SetPollConfig.
+var DefaultPollConfig = PollConfig{
+	Interval: 5 * time.Minute,
+	Jitter:   30 * time.Second,
+}
+
+// Store is a fi.LiveStateStore that polls a Getter per task, on a
+// per-resource-kind schedule, and publishes fi.DriftEvents whenever the
+// observed object diverges from the desired one.
+type Store struct {
+	tasks map[string]fi.Task
+
+	mutex      sync.Mutex
+	getters    map[string]Getter
+	pollConfig map[string]PollConfig
+	lastSeen   map[string]fi.Task
+
+	events chan fi.DriftEvent
+}
+
+var _ fi.LiveStateStore = &Store{}
+
+// NewStore builds a Store that will poll the given tasks once a Getter has
+// been registered for their kind.
+func NewStore(tasks map[string]fi.Task) *Store {
+	return &Store{
+		tasks:      tasks,
+		getters:    make(map[string]Getter),
+		pollConfig: make(map[string]PollConfig),
+		lastSeen:   make(map[string]fi.Task),
+		events:     make(chan fi.DriftEvent, 16),
+	}
+}
+
+// RegisterGetter wires up the Getter used to poll tasks of the given kind,
+// e.g. "awstasks.Instance" or "gcetasks.Instance".
+func (s *Store) RegisterGetter(kind string, getter Getter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.getters[kind] = getter
+}
+
+// SetPollConfig overrides the polling interval/jitter for a resource kind.
+func (s *Store) SetPollConfig(kind string, config PollConfig) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pollConfig[kind] = config
+}
+
+// Events implements fi.LiveStateStore.
+func (s *Store) Events() <-chan fi.DriftEvent {
+	return s.events
+}
+
+// pollable is a task with a registered Getter, resolved once up front so
+// Start doesn't have to take s.mutex again for every poll.
+type pollable struct {
+	name   string
+	task   fi.Task
+	kind   string
+	getter Getter
+}
+
+// Start implements fi.LiveStateStore. It runs one synchronous poll per task
+// that has a registered Getter, then returns, having launched a background
+// goroutine per task to keep polling until stop is closed. It does not
+// block waiting for that background polling, only for the first pass.
+func (s *Store) Start(stop <-chan struct{}) error {
+	var pollables []pollable
+	for name, task := range s.tasks {
+		kind := taskKind(task)
+
+		s.mutex.Lock()
+		getter, ok := s.getters[kind]
+		s.mutex.Unlock()
+		if !ok {
+			glog.V(4).Infof("no live-state getter registered for kind %q, skipping %s", kind, name)
+			continue
+		}
+		pollables = append(pollables, pollable{name: name, task: task, kind: kind, getter: getter})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(pollables))
+	for _, p := range pollables {
+		go func(p pollable) {
+			defer wg.Done()
+			s.poll(p.name, p.task, p.kind, p.getter)
+		}(p)
+	}
+	wg.Wait()
+
+	for _, p := range pollables {
+		go s.pollLoop(stop, p.name, p.task, p.kind, p.getter)
+	}
+	return nil
+}
+
+// pollLoop re-polls on an interval (plus jitter) until stop is closed. The
+// first poll has already happened in Start, so this only waits and polls
+// again; it never returns before stop fires.
+func (s *Store) pollLoop(stop <-chan struct{}, name string, task fi.Task, kind string, getter Getter) {
+	for {
+		config := s.configFor(kind)
+		interval := config.Interval
+		if config.Jitter > 0 {
+			interval += time.Duration(rand.Int63n(int64(config.Jitter)))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+			s.poll(name, task, kind, getter)
+		}
+	}
+}
+
+func (s *Store) configFor(kind string) PollConfig {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if config, ok := s.pollConfig[kind]; ok {
+		return config
+	}
+	return DefaultPollConfig
+}
+
+func (s *Store) poll(name string, task fi.Task, kind string, getter Getter) {
+	start := time.Now()
+	actual, err := getter.Get(task)
+	pollLatency.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	if err != nil {
+		pollErrors.WithLabelValues(kind).Inc()
+		glog.Warningf("live-state poll of %s (%s) failed: %v", name, kind, err)
+		return
+	}
+
+	s.mutex.Lock()
+	s.lastSeen[name] = actual
+	s.mutex.Unlock()
+
+	changes := reflect.New(reflect.TypeOf(task).Elem()).Interface().(fi.Task)
+	changeList, err := fi.DiffTask(actual, task, changes)
+	if err != nil {
+		glog.Warningf("live-state diff of %s (%s) failed: %v", name, kind, err)
+		return
+	}
+	if len(changeList) == 0 {
+		return
+	}
+
+	driftEventsTotal.WithLabelValues(kind).Inc()
+	s.events <- fi.DriftEvent{
+		TaskName:   name,
+		Changes:    changeList,
+		ObservedAt: time.Now(),
+	}
+}
+
+func taskKind(task fi.Task) string {
+	t := reflect.TypeOf(task)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package livestate
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+type fakeTask struct{}
+
+func (f *fakeTask) Run(c *fi.Context) error { return nil }
+
+type fakeGetter struct{}
+
+func (g *fakeGetter) Get(task fi.Task) (fi.Task, error) {
+	return task, nil
+}
+
+// TestStoreStartDoesNotBlock guards against Start hanging forever: it must
+// do one synchronous pass per task and then return, leaving continued
+// polling to run in the background until stop is closed.
+func TestStoreStartDoesNotBlock(t *testing.T) {
+	task := &fakeTask{}
+	kind := taskKind(task)
+
+	s := NewStore(map[string]fi.Task{"t1": task})
+	s.RegisterGetter(kind, &fakeGetter{})
+	s.SetPollConfig(kind, PollConfig{Interval: time.Hour})
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Start(stop)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Start did not return within 2s - deadlocked")
+	}
+}